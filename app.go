@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// App carries the services subcommands depend on, each initialized lazily
+// on first use. A subcommand that never touches the global obsidian.json
+// (e.g. "template list") never pays for loading or parsing it, and tests can
+// substitute fakes for any one service without standing up the rest.
+type App struct {
+	config *Config
+
+	globalConfig     *ObsidianConfig
+	globalConfigPath string
+
+	templates map[string]*Template
+
+	randSource io.Reader
+}
+
+// NewApp returns an App with its lazy fields unset; the first call to each
+// accessor populates its own field only.
+func NewApp() *App {
+	return &App{randSource: rand.Reader, templates: make(map[string]*Template)}
+}
+
+// Config lazily loads and caches the user config.toml.
+func (a *App) Config() (*Config, error) {
+	if a.config != nil {
+		return a.config, nil
+	}
+	path, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	a.config = cfg
+	return a.config, nil
+}
+
+// GlobalConfig lazily loads and caches Obsidian's global obsidian.json,
+// returning the config and the path it was (or will be) read from.
+func (a *App) GlobalConfig() (*ObsidianConfig, string, error) {
+	if a.globalConfig != nil {
+		return a.globalConfig, a.globalConfigPath, nil
+	}
+	path, err := globalConfigPath()
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := loadGlobalConfig(path)
+	if err != nil {
+		return nil, "", err
+	}
+	a.globalConfig, a.globalConfigPath = cfg, path
+	return a.globalConfig, a.globalConfigPath, nil
+}
+
+// SaveGlobalConfig persists the config previously returned by GlobalConfig
+// back to the path it was loaded from, atomically. It returns an error if
+// GlobalConfig has not been called yet.
+func (a *App) SaveGlobalConfig() error {
+	if a.globalConfig == nil {
+		return fmt.Errorf("SaveGlobalConfig called before GlobalConfig")
+	}
+	return saveGlobalConfig(a.globalConfigPath, a.globalConfig)
+}
+
+// Template lazily loads and caches a named template bundle, resolved the
+// same way loadTemplate resolves one (templateDir, then the user templates
+// directory, then the built-in bundle).
+func (a *App) Template(name, templateDir string) (*Template, error) {
+	key := templateDir + "\x00" + name
+	if t, ok := a.templates[key]; ok {
+		return t, nil
+	}
+	t, err := loadTemplate(name, templateDir)
+	if err != nil {
+		return nil, err
+	}
+	a.templates[key] = t
+	return t, nil
+}
+
+// RandSource returns the random source used to generate vault IDs
+// (crypto/rand.Reader by default; tests may substitute a deterministic one).
+func (a *App) RandSource() io.Reader {
+	return a.randSource
+}
+
+// app is populated by rootCmd's PersistentPreRunE before any subcommand
+// runs.
+var app *App
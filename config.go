@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// Config holds user-level defaults read from config.toml. A zero-value
+// field means "use petiteplatypus's built-in default" rather than an
+// explicit override.
+type Config struct {
+	Template        string          `toml:"template"`
+	DefaultVaultDir string          `toml:"default_vault_dir"`
+	OpenSchemes     map[string]bool `toml:"open_schemes"`
+	OpenByDefault   *bool           `toml:"open_by_default"`
+	LogLevel        string          `toml:"log_level"`
+	LogFormat       string          `toml:"log_format"`
+}
+
+// openByDefault reports whether newly generated vaults should be marked
+// Open: true, defaulting to true when the config doesn't say otherwise.
+func (c *Config) openByDefault() bool {
+	if c.OpenByDefault == nil {
+		return true
+	}
+	return *c.OpenByDefault
+}
+
+const exampleConfig = `# petiteplatypus user config
+# Uncomment and edit any of the following to override petiteplatypus's defaults.
+
+# template = "default"
+# default_vault_dir = "~/Notes"
+# open_by_default = true
+# log_level = "warn"
+# log_format = "text"
+
+# [open_schemes]
+# vscode = true
+# chrome-extension = true
+`
+
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write an example petiteplatypus config file",
+	Args:  cobra.NoArgs,
+	RunE:  initConfig,
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing config file")
+	rootCmd.AddCommand(initCmd)
+}
+
+// userConfigPath returns ~/.config/petiteplatypus/config.toml (or the
+// platform equivalent reported by UserConfigDirectory).
+func userConfigPath() (string, error) {
+	configDir, err := UserConfigDirectory()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "petiteplatypus", "config.toml"), nil
+}
+
+// loadConfig reads config.toml, returning a zero-value Config if the file
+// doesn't exist yet.
+func loadConfig(path string) (*Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return &cfg, nil
+}
+
+func initConfig(cmd *cobra.Command, args []string) error {
+	path, err := userConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if !initForce {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(exampleConfig), 0o644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	fmt.Printf("Wrote example config to %s\n", path)
+	return nil
+}
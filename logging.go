@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// multiHandler fans a single slog.Record out to several handlers, letting us
+// keep human-readable text on stderr while also writing structured JSON to a
+// log file.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+func newMultiHandler(handlers ...slog.Handler) *multiHandler {
+	return &multiHandler{handlers: handlers}
+}
+
+func (m *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, h := range m.handlers {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+func (m *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// logFileMaxBytes is the size at which a --log-file rolls over.
+const logFileMaxBytes = 10 * 1024 * 1024 // 10 MiB
+
+// rotatingLogFile is an io.WriteCloser that rolls logFile over to
+// logFile+".bak" (replacing any previous backup, mirroring the pattern
+// saveGlobalConfig uses for obsidian.json) once it grows past
+// logFileMaxBytes, so --log-file can't grow without bound.
+type rotatingLogFile struct {
+	path string
+	f    *os.File
+	size int64
+}
+
+func newRotatingLogFile(path string) (*rotatingLogFile, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &rotatingLogFile{path: path, f: f, size: info.Size()}, nil
+}
+
+func (r *rotatingLogFile) Write(p []byte) (int, error) {
+	if r.size > 0 && r.size+int64(len(p)) > logFileMaxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingLogFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("failed to close log file before rotating: %w", err)
+	}
+	if err := os.Rename(r.path, r.path+".bak"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to roll over log file: %w", err)
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen log file after rotating: %w", err)
+	}
+	r.f = f
+	r.size = 0
+	return nil
+}
+
+func (r *rotatingLogFile) Close() error {
+	return r.f.Close()
+}
+
+// levelFromFlags resolves the effective slog level. An explicit --log-level
+// always wins; otherwise we fall back to the legacy -v/-vv/-vvv count so
+// existing muscle memory keeps working.
+func levelFromFlags(logLevel string, verbosity int) (slog.Level, error) {
+	if logLevel != "" {
+		switch strings.ToLower(logLevel) {
+		case "debug":
+			return slog.LevelDebug, nil
+		case "info":
+			return slog.LevelInfo, nil
+		case "warn":
+			return slog.LevelWarn, nil
+		case "error":
+			return slog.LevelError, nil
+		default:
+			return 0, fmt.Errorf("invalid --log-level %q (want debug|info|warn|error)", logLevel)
+		}
+	}
+
+	switch {
+	case verbosity >= 2:
+		return slog.LevelDebug, nil
+	case verbosity == 1:
+		return slog.LevelInfo, nil
+	default:
+		return slog.LevelWarn, nil
+	}
+}
+
+// initLogging builds the root slog.Logger for the process. Stderr always
+// gets a human-facing handler (text or json per --log-format); when
+// --log-file is set, a second JSON handler is fanned out to that file so
+// downstream tooling can grep structured records regardless of what's going
+// to the terminal. The log file rolls over to a single ".bak" once it passes
+// logFileMaxBytes. The returned close func flushes/closes the log file and
+// must be called before the process exits.
+func initLogging(logLevel, logFormat, logFile string, verbosity int) (*slog.Logger, func() error, error) {
+	level, err := levelFromFlags(logLevel, verbosity)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var stderrHandler slog.Handler
+	switch strings.ToLower(logFormat) {
+	case "", "text":
+		stderrHandler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		stderrHandler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return nil, nil, fmt.Errorf("invalid --log-format %q (want text|json)", logFormat)
+	}
+
+	handlers := []slog.Handler{stderrHandler}
+	closeFn := func() error { return nil }
+
+	if logFile != "" {
+		f, err := newRotatingLogFile(logFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		handlers = append(handlers, slog.NewJSONHandler(f, opts))
+		closeFn = f.Close
+	}
+
+	return slog.New(newMultiHandler(handlers...)), closeFn, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestVault is one entry in a declarative vaults manifest.
+type ManifestVault struct {
+	Name     string `yaml:"name"`
+	Path     string `yaml:"path"`
+	Template string `yaml:"template,omitempty"`
+	Open     bool   `yaml:"open"`
+}
+
+// Manifest is the top-level shape of a `petiteplatypus apply -f` file.
+type Manifest struct {
+	Vaults []ManifestVault `yaml:"vaults"`
+}
+
+var (
+	manifestFile string
+	applyPrune   bool
+	applyDryRun  bool
+	applyRedact  bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile registered vaults against a declarative manifest",
+	Args:  cobra.NoArgs,
+	RunE:  applyManifest,
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&manifestFile, "file", "f", "", "path to the vaults manifest (required)")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "remove registered vaults that are not listed in the manifest")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "log intended changes without touching disk")
+	applyCmd.Flags().BoolVar(&applyRedact, "redact", false, "hide absolute paths in logged output")
+	_ = applyCmd.MarkFlagRequired("file")
+	rootCmd.AddCommand(applyCmd)
+}
+
+// expandPath expands a leading "~" to the user's home directory and any
+// "$VAR"/"${VAR}" references, without shelling out, so manifests stay
+// portable across machines.
+func expandPath(p string) (string, error) {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+	}
+	return os.ExpandEnv(p), nil
+}
+
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// redactedPath returns p unchanged, or "<redacted>" when --redact is set, so
+// logs can be shared without leaking local directory layout.
+func redactedPath(p string) string {
+	if applyRedact {
+		return "<redacted>"
+	}
+	return p
+}
+
+func applyManifest(cmd *cobra.Command, args []string) error {
+	manifest, err := loadManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+
+	config, _, err := app.GlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	type wantedVault struct {
+		name string
+		mv   ManifestVault
+	}
+	wanted := make(map[string]wantedVault, len(manifest.Vaults))
+	for _, mv := range manifest.Vaults {
+		expanded, err := expandPath(mv.Path)
+		if err != nil {
+			return fmt.Errorf("vault %q: %w", mv.Name, err)
+		}
+		absPath, err := filepath.Abs(expanded)
+		if err != nil {
+			return fmt.Errorf("vault %q: failed to resolve %s: %w", mv.Name, mv.Path, err)
+		}
+		wanted[absPath] = wantedVault{name: mv.Name, mv: mv}
+	}
+
+	byPath := make(map[string]string, len(config.Vaults))
+	for id, v := range config.Vaults {
+		byPath[v.Path] = id
+	}
+
+	changed := false
+
+	paths := make([]string, 0, len(wanted))
+	for p := range wanted {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, absPath := range paths {
+		w := wanted[absPath]
+		if id, ok := byPath[absPath]; ok {
+			v := config.Vaults[id]
+			if v.Open != w.mv.Open {
+				logger.Info("updating vault open flag", slog.String("vault", w.name), slog.String("path", redactedPath(absPath)), slog.Bool("open", w.mv.Open))
+				if !applyDryRun {
+					v.Open = w.mv.Open
+					v.Ts = time.Now().UnixMilli()
+					config.Vaults[id] = v
+					changed = true
+				}
+			}
+			continue
+		}
+
+		logger.Info("creating vault", slog.String("vault", w.name), slog.String("path", redactedPath(absPath)), slog.String("template", w.mv.Template))
+		if applyDryRun {
+			continue
+		}
+		vaultID, err := scaffoldVault(absPath, w.mv.Template, templateDir, redactedPath)
+		if err != nil {
+			return fmt.Errorf("vault %q: failed to scaffold: %w", w.name, err)
+		}
+		config.Vaults[vaultID] = VaultConfig{
+			Path: absPath,
+			Ts:   time.Now().UnixMilli(),
+			Open: w.mv.Open,
+		}
+		changed = true
+	}
+
+	if applyPrune {
+		ids := make([]string, 0, len(byPath))
+		for absPath, id := range byPath {
+			if _, ok := wanted[absPath]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			v := config.Vaults[id]
+			logger.Info("pruning vault not present in manifest", slog.String("vault_id", id), slog.String("path", redactedPath(v.Path)))
+			if !applyDryRun {
+				delete(config.Vaults, id)
+				changed = true
+			}
+		}
+	}
+
+	if applyDryRun {
+		fmt.Println("Dry run: no changes written")
+		return nil
+	}
+
+	if !changed {
+		fmt.Println("No changes needed")
+		return nil
+	}
+
+	if err := app.SaveGlobalConfig(); err != nil {
+		return fmt.Errorf("failed to save global config: %w", err)
+	}
+
+	fmt.Println("Applied manifest")
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// obsidianConfigDir returns the directory where the Obsidian app itself
+// keeps its global obsidian.json. This does not follow Go's generic
+// os.UserConfigDir() on every platform: Obsidian on macOS lives under
+// "~/Library/Application Support/obsidian" and on Windows under
+// "%APPDATA%\obsidian", not the XDG-style path os.UserConfigDir() would
+// report there.
+func obsidianConfigDir() (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return obsidianConfigDirDarwin()
+	case "windows":
+		return obsidianConfigDirWindows()
+	default:
+		return obsidianConfigDirUnix()
+	}
+}
+
+func obsidianConfigDirDarwin() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "Application Support", "obsidian"), nil
+}
+
+func obsidianConfigDirWindows() (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return "", fmt.Errorf("%%APPDATA%% is not set")
+	}
+	return filepath.Join(appData, "obsidian"), nil
+}
+
+func obsidianConfigDirUnix() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "obsidian"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "obsidian"), nil
+}
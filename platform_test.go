@@ -0,0 +1,72 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestObsidianConfigDirDarwin(t *testing.T) {
+	t.Setenv("HOME", "/Users/alice")
+
+	got, err := obsidianConfigDirDarwin()
+	if err != nil {
+		t.Fatalf("obsidianConfigDirDarwin() error = %v", err)
+	}
+
+	want := filepath.Join("/Users/alice", "Library", "Application Support", "obsidian")
+	if got != want {
+		t.Errorf("obsidianConfigDirDarwin() = %q, want %q", got, want)
+	}
+}
+
+func TestObsidianConfigDirWindows(t *testing.T) {
+	t.Setenv("APPDATA", `C:\Users\alice\AppData\Roaming`)
+
+	got, err := obsidianConfigDirWindows()
+	if err != nil {
+		t.Fatalf("obsidianConfigDirWindows() error = %v", err)
+	}
+
+	want := filepath.Join(`C:\Users\alice\AppData\Roaming`, "obsidian")
+	if got != want {
+		t.Errorf("obsidianConfigDirWindows() = %q, want %q", got, want)
+	}
+}
+
+func TestObsidianConfigDirWindowsMissingAppData(t *testing.T) {
+	t.Setenv("APPDATA", "")
+
+	if _, err := obsidianConfigDirWindows(); err == nil {
+		t.Fatal("obsidianConfigDirWindows() expected an error when APPDATA is unset")
+	}
+}
+
+func TestObsidianConfigDirUnixWithXDG(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/alice/.config")
+	t.Setenv("HOME", "/home/alice")
+
+	got, err := obsidianConfigDirUnix()
+	if err != nil {
+		t.Fatalf("obsidianConfigDirUnix() error = %v", err)
+	}
+
+	want := filepath.Join("/home/alice/.config", "obsidian")
+	if got != want {
+		t.Errorf("obsidianConfigDirUnix() = %q, want %q", got, want)
+	}
+}
+
+func TestObsidianConfigDirUnixFallsBackToHome(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/alice")
+
+	got, err := obsidianConfigDirUnix()
+	if err != nil {
+		t.Fatalf("obsidianConfigDirUnix() error = %v", err)
+	}
+
+	want := filepath.Join("/home/alice", ".config", "obsidian")
+	if got != want {
+		t.Errorf("obsidianConfigDirUnix() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/default
+var builtinTemplatesFS embed.FS
+
+const builtinTemplatesRoot = "templates"
+
+// builtinTemplateName is the bundle shipped with the binary and used when
+// no template name is given (neither --template nor config.toml's template).
+const builtinTemplateName = "default"
+
+// TemplateData is the set of values a bundle's files may reference via Go
+// text/template interpolation, e.g. "{{.VaultName}}".
+type TemplateData struct {
+	VaultID   string
+	VaultName string
+	CreatedAt string
+}
+
+// Template is a named bundle of files to scaffold a new vault: everything
+// under its "obsidian/" subtree is copied into .obsidian/, and everything
+// under "vault/" is copied into the vault root. Files are always rendered
+// through text/template, so plain files without actions pass through
+// unchanged.
+type Template struct {
+	Name string
+	FS   fs.FS
+}
+
+// userTemplatesDir returns $XDG_CONFIG_HOME/petiteplatypus/templates (or the
+// platform equivalent reported by UserConfigDirectory), where users can drop
+// their own named bundles.
+func userTemplatesDir() (string, error) {
+	configDir, err := UserConfigDirectory()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "petiteplatypus", "templates"), nil
+}
+
+// loadTemplate resolves a template by name, preferring a user-supplied
+// templateDir, then $XDG_CONFIG_HOME/petiteplatypus/templates/<name>, then
+// falling back to the built-in bundle embedded in the binary.
+func loadTemplate(name, templateDir string) (*Template, error) {
+	if name == "" {
+		name = builtinTemplateName
+	}
+
+	if templateDir != "" {
+		dir := filepath.Join(templateDir, name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return &Template{Name: name, FS: os.DirFS(dir)}, nil
+		}
+	}
+
+	if dir, err := userTemplatesDir(); err == nil {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return &Template{Name: name, FS: os.DirFS(candidate)}, nil
+		}
+	}
+
+	if name == builtinTemplateName {
+		sub, err := fs.Sub(builtinTemplatesFS, path.Join(builtinTemplatesRoot, builtinTemplateName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load built-in template: %w", err)
+		}
+		return &Template{Name: name, FS: sub}, nil
+	}
+
+	return nil, fmt.Errorf("template %q not found (looked in --template-dir, %s, and the built-in bundle)", name, mustUserTemplatesDir())
+}
+
+func mustUserTemplatesDir() string {
+	dir, err := userTemplatesDir()
+	if err != nil {
+		return "<user config dir>/petiteplatypus/templates"
+	}
+	return dir
+}
+
+// listTemplates returns the names of every discoverable template: the
+// built-in bundle plus anything under the user templates directory and, if
+// given, templateDir.
+func listTemplates(templateDir string) ([]string, error) {
+	seen := map[string]bool{builtinTemplateName: true}
+
+	addDirEntries := func(dir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				seen[e.Name()] = true
+			}
+		}
+		return nil
+	}
+
+	if userDir, err := userTemplatesDir(); err == nil {
+		if err := addDirEntries(userDir); err != nil {
+			return nil, fmt.Errorf("failed to list user templates: %w", err)
+		}
+	}
+	if templateDir != "" {
+		if err := addDirEntries(templateDir); err != nil {
+			return nil, fmt.Errorf("failed to list templates in %s: %w", templateDir, err)
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Apply renders every file in the template's "obsidian/" subtree (recursing
+// into nested directories, e.g. "obsidian/snippets/theme.css") into
+// obsidianDir, and every file under "vault/" into vaultPath. redact is
+// applied to every path logged, so a caller with a --redact-style flag
+// doesn't leak real paths at higher verbosity.
+func (t *Template) Apply(obsidianDir, vaultPath string, data TemplateData, redact func(string) string) error {
+	if err := t.applySubtree("obsidian", obsidianDir, data, redact); err != nil {
+		return err
+	}
+	if err := t.applySubtree("vault", vaultPath, data, redact); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *Template) applySubtree(subdir, destDir string, data TemplateData, redact func(string) string) error {
+	walkErr := fs.WalkDir(t.FS, subdir, func(fsPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		raw, err := fs.ReadFile(t.FS, fsPath)
+		if err != nil {
+			return fmt.Errorf("failed to read template file %s: %w", fsPath, err)
+		}
+
+		tmpl, err := template.New(entry.Name()).Parse(string(raw))
+		if err != nil {
+			return fmt.Errorf("failed to parse template file %s: %w", fsPath, err)
+		}
+
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return fmt.Errorf("failed to render template file %s: %w", fsPath, err)
+		}
+
+		rel := strings.TrimPrefix(fsPath, subdir+"/")
+		destPath := filepath.Join(destDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", destPath, err)
+		}
+		if err := os.WriteFile(destPath, rendered.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", destPath, err)
+		}
+		logger.Debug("wrote templated file", slog.String("template", t.Name), slog.String("path", redact(destPath)))
+		return nil
+	})
+	if walkErr != nil {
+		if os.IsNotExist(walkErr) {
+			logger.Debug("template has no files for subtree", slog.String("template", t.Name), slog.String("subtree", subdir))
+			return nil
+		}
+		return fmt.Errorf("failed to read %s template files: %w", subdir, walkErr)
+	}
+
+	return nil
+}
+
+func newTemplateData(vaultID, vaultPath string) TemplateData {
+	return TemplateData{
+		VaultID:   vaultID,
+		VaultName: filepath.Base(vaultPath),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	vaultsListJSON bool
+	vaultsPurge    bool
+)
+
+var vaultsCmd = &cobra.Command{
+	Use:   "vaults",
+	Short: "Manage vaults registered in the global obsidian.json",
+}
+
+var vaultsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered vaults",
+	Args:  cobra.NoArgs,
+	RunE:  listVaults,
+}
+
+var vaultsRemoveCmd = &cobra.Command{
+	Use:   "remove <id-or-path>",
+	Short: "Remove a registered vault",
+	Args:  cobra.ExactArgs(1),
+	RunE:  removeVault,
+}
+
+var vaultsImportCmd = &cobra.Command{
+	Use:   "import <existing-path>",
+	Short: "Register an already-existing vault directory",
+	Args:  cobra.ExactArgs(1),
+	RunE:  importVault,
+}
+
+func init() {
+	vaultsListCmd.Flags().BoolVar(&vaultsListJSON, "json", false, "print the vault list as JSON instead of a table")
+	vaultsRemoveCmd.Flags().BoolVar(&vaultsPurge, "purge", false, "also delete the vault directory from disk")
+
+	vaultsCmd.AddCommand(vaultsListCmd)
+	vaultsCmd.AddCommand(vaultsRemoveCmd)
+	vaultsCmd.AddCommand(vaultsImportCmd)
+	rootCmd.AddCommand(vaultsCmd)
+}
+
+// globalConfigPath returns the path to Obsidian's global obsidian.json.
+func globalConfigPath() (string, error) {
+	configDir, err := obsidianConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get obsidian config directory: %w", err)
+	}
+	return filepath.Join(configDir, "obsidian.json"), nil
+}
+
+// loadGlobalConfig reads the global obsidian.json, returning an
+// ObsidianConfig with no vaults if the file doesn't exist yet. A missing
+// openSchemes map is filled with the vscode and chrome-extension defaults.
+func loadGlobalConfig(path string) (*ObsidianConfig, error) {
+	config := &ObsidianConfig{}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse existing config: %w", err)
+		}
+		logger.Debug("parsed existing global config", slog.Int("vaults_in_config", len(config.Vaults)))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	} else {
+		logger.Debug("no existing global config found, creating new one", slog.String("path", path))
+	}
+
+	if config.Vaults == nil {
+		config.Vaults = make(map[string]VaultConfig)
+	}
+	if config.OpenSchemes == nil {
+		config.OpenSchemes = map[string]bool{
+			"vscode":           true,
+			"chrome-extension": true,
+		}
+	}
+
+	return config, nil
+}
+
+// saveGlobalConfig writes config to path atomically: it marshals to
+// path+".tmp", backs up any existing file to path+".bak", then renames the
+// temp file into place. A crashed run can therefore never leave obsidian.json
+// truncated or corrupt.
+func saveGlobalConfig(path string, config *ObsidianConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create obsidian config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write temp config file: %w", err)
+	}
+
+	if existing, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+".bak", existing, 0o644); err != nil {
+			return fmt.Errorf("failed to write config backup: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read existing config for backup: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to install updated config: %w", err)
+	}
+	logger.Debug("wrote global obsidian config", slog.String("path", path), slog.Int("vaults_in_config", len(config.Vaults)))
+
+	return nil
+}
+
+func listVaults(cmd *cobra.Command, args []string) error {
+	config, _, err := app.GlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	ids := make([]string, 0, len(config.Vaults))
+	for id := range config.Vaults {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	if vaultsListJSON {
+		data, err := json.MarshalIndent(config.Vaults, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal vault list: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPATH\tTS\tOPEN")
+	for _, id := range ids {
+		v := config.Vaults[id]
+		fmt.Fprintf(w, "%s\t%s\t%d\t%t\n", id, v.Path, v.Ts, v.Open)
+	}
+	return w.Flush()
+}
+
+// findVaultID resolves idOrPath to a registered vault ID, accepting either
+// the vault ID itself or its on-disk path.
+func findVaultID(config *ObsidianConfig, idOrPath string) (string, error) {
+	if _, ok := config.Vaults[idOrPath]; ok {
+		return idOrPath, nil
+	}
+
+	absPath, err := filepath.Abs(idOrPath)
+	if err == nil {
+		for id, v := range config.Vaults {
+			if v.Path == absPath || v.Path == idOrPath {
+				return id, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no registered vault matches %q", idOrPath)
+}
+
+func removeVault(cmd *cobra.Command, args []string) error {
+	config, _, err := app.GlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	id, err := findVaultID(config, args[0])
+	if err != nil {
+		return err
+	}
+	v := config.Vaults[id]
+
+	if vaultsPurge {
+		logger.Info("purging vault directory", slog.String("vault_id", id), slog.String("path", v.Path))
+		if err := os.RemoveAll(v.Path); err != nil {
+			return fmt.Errorf("failed to purge vault directory %s: %w", v.Path, err)
+		}
+	}
+
+	delete(config.Vaults, id)
+	if err := app.SaveGlobalConfig(); err != nil {
+		return fmt.Errorf("failed to save global config: %w", err)
+	}
+
+	fmt.Printf("Removed vault %s (%s)\n", id, v.Path)
+	return nil
+}
+
+func importVault(cmd *cobra.Command, args []string) error {
+	vaultPath, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	info, err := os.Stat(vaultPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", vaultPath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", vaultPath)
+	}
+
+	config, _, err := app.GlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	for id, v := range config.Vaults {
+		if v.Path == vaultPath {
+			return fmt.Errorf("%s is already registered as vault %s", vaultPath, id)
+		}
+	}
+
+	vaultID, err := generateVaultID()
+	if err != nil {
+		return fmt.Errorf("failed to generate vault ID: %w", err)
+	}
+
+	config.Vaults[vaultID] = VaultConfig{
+		Path: vaultPath,
+		Ts:   time.Now().UnixMilli(),
+		Open: true,
+	}
+
+	if err := app.SaveGlobalConfig(); err != nil {
+		return fmt.Errorf("failed to save global config: %w", err)
+	}
+
+	fmt.Printf("Imported vault %s as %s\n", vaultPath, vaultID)
+	return nil
+}
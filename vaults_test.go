@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	os.Exit(m.Run())
+}
+
+func TestSaveGlobalConfigWritesAtomicallyAndBacksUpExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "obsidian.json")
+
+	first := &ObsidianConfig{Vaults: map[string]VaultConfig{"aaa": {Path: "/vaults/aaa"}}}
+	if err := saveGlobalConfig(path, first); err != nil {
+		t.Fatalf("saveGlobalConfig() first write error = %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected %s.tmp to be gone after rename, stat err = %v", path, err)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("expected no %s.bak before any prior config existed, stat err = %v", path, err)
+	}
+
+	second := &ObsidianConfig{Vaults: map[string]VaultConfig{"bbb": {Path: "/vaults/bbb"}}}
+	if err := saveGlobalConfig(path, second); err != nil {
+		t.Fatalf("saveGlobalConfig() second write error = %v", err)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("expected %s.bak after overwriting an existing config: %v", path, err)
+	}
+	var backedUp ObsidianConfig
+	if err := json.Unmarshal(backup, &backedUp); err != nil {
+		t.Fatalf("failed to parse backup: %v", err)
+	}
+	if _, ok := backedUp.Vaults["aaa"]; !ok {
+		t.Errorf("backup should contain the first write's vault, got %+v", backedUp.Vaults)
+	}
+
+	loaded, err := loadGlobalConfig(path)
+	if err != nil {
+		t.Fatalf("loadGlobalConfig() error = %v", err)
+	}
+	if _, ok := loaded.Vaults["bbb"]; !ok {
+		t.Errorf("installed config should contain the second write's vault, got %+v", loaded.Vaults)
+	}
+	if _, ok := loaded.Vaults["aaa"]; ok {
+		t.Errorf("installed config should not contain the first write's vault, got %+v", loaded.Vaults)
+	}
+}
+
+func TestFindVaultID(t *testing.T) {
+	config := &ObsidianConfig{Vaults: map[string]VaultConfig{
+		"abc123": {Path: "/home/alice/notes"},
+	}}
+
+	t.Run("by id", func(t *testing.T) {
+		got, err := findVaultID(config, "abc123")
+		if err != nil || got != "abc123" {
+			t.Errorf("findVaultID(id) = %q, %v, want \"abc123\", nil", got, err)
+		}
+	})
+
+	t.Run("by path", func(t *testing.T) {
+		got, err := findVaultID(config, "/home/alice/notes")
+		if err != nil || got != "abc123" {
+			t.Errorf("findVaultID(path) = %q, %v, want \"abc123\", nil", got, err)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		if _, err := findVaultID(config, "nope"); err == nil {
+			t.Error("findVaultID(unknown) expected an error, got nil")
+		}
+	})
+}
+
+func newTestApp(t *testing.T, dir string) *App {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	return NewApp()
+}
+
+func TestRemoveVault(t *testing.T) {
+	dir := t.TempDir()
+	app = newTestApp(t, dir)
+
+	config, path, err := app.GlobalConfig()
+	if err != nil {
+		t.Fatalf("GlobalConfig() error = %v", err)
+	}
+	config.Vaults["abc123"] = VaultConfig{Path: filepath.Join(dir, "notes")}
+	if err := app.SaveGlobalConfig(); err != nil {
+		t.Fatalf("SaveGlobalConfig() error = %v", err)
+	}
+
+	vaultsPurge = false
+	if err := removeVault(nil, []string{"abc123"}); err != nil {
+		t.Fatalf("removeVault() error = %v", err)
+	}
+
+	reloaded, err := loadGlobalConfig(path)
+	if err != nil {
+		t.Fatalf("loadGlobalConfig() error = %v", err)
+	}
+	if _, ok := reloaded.Vaults["abc123"]; ok {
+		t.Errorf("removeVault() left the vault registered: %+v", reloaded.Vaults)
+	}
+}
+
+func TestImportVault(t *testing.T) {
+	dir := t.TempDir()
+	app = newTestApp(t, dir)
+
+	vaultPath := filepath.Join(dir, "existing-vault")
+	if err := os.MkdirAll(vaultPath, 0o755); err != nil {
+		t.Fatalf("failed to create vault dir: %v", err)
+	}
+
+	if err := importVault(nil, []string{vaultPath}); err != nil {
+		t.Fatalf("importVault() error = %v", err)
+	}
+
+	config, _, err := app.GlobalConfig()
+	if err != nil {
+		t.Fatalf("GlobalConfig() error = %v", err)
+	}
+	found := false
+	for _, v := range config.Vaults {
+		if v.Path == vaultPath {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("importVault() did not register %s, got %+v", vaultPath, config.Vaults)
+	}
+
+	if err := importVault(nil, []string{vaultPath}); err == nil {
+		t.Error("importVault() on an already-registered path expected an error, got nil")
+	}
+}